@@ -0,0 +1,185 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// RunContext holds the mutable state that used to live in package-level
+// variables (fieldNameCount and friends) so a single process can drive
+// multiple concurrent Parse/Generate runs without one run's naming
+// collisions leaking into another's.
+type RunContext struct {
+	mu             sync.Mutex
+	symbols        map[string]interface{}
+	symbolIndex    map[string]int
+	fieldNameCount map[string]int
+}
+
+// NewRunContext returns an empty, ready-to-use RunContext.
+func NewRunContext() *RunContext {
+	return &RunContext{
+		symbols:        make(map[string]interface{}),
+		symbolIndex:    make(map[string]int),
+		fieldNameCount: make(map[string]int),
+	}
+}
+
+// Define registers decl under name, keyed by idx - the position of the
+// declaring file in the original input list. Resolving a name shared by
+// two files always keeps the lowest-idx declaration, regardless of which
+// worker goroutine happens to reach Define first, so the outcome matches
+// the serial walk order and stays the same across runs. A mutex alone
+// would only make the map safe to share, not deterministic: lock
+// acquisition order tracks OS scheduling, not paths' input order.
+func (rc *RunContext) Define(name string, decl interface{}, idx int) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if existingIdx, exists := rc.symbolIndex[name]; !exists || idx < existingIdx {
+		rc.symbols[name] = decl
+		rc.symbolIndex[name] = idx
+	}
+}
+
+// Lookup returns the declaration registered under name, if any.
+func (rc *RunContext) Lookup(name string) (interface{}, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	v, ok := rc.symbols[name]
+	return v, ok
+}
+
+// NextFieldName disambiguates name against every other field with the same
+// name seen so far in this run, mirroring the old package-level
+// fieldNameCount bookkeeping but scoped to rc instead of the process.
+func (rc *RunContext) NextFieldName(name string) string {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.fieldNameCount[name]++
+	if n := rc.fieldNameCount[name]; n > 1 {
+		return fmt.Sprintf("%s%d", name, n)
+	}
+	return name
+}
+
+// FileJob is one file's worth of work: parse it into an XSDSchema and, when
+// parsing succeeds, hand the result to a generator. Index is the file's position
+// in the original input list, threaded through to parse so a parser can
+// pass it on to RunContext.Define and get a result deterministic by input
+// order instead of goroutine scheduling order.
+type FileJob struct {
+	Path  string
+	Index int
+}
+
+// FileResult is the outcome of processing a single FileJob.
+type FileResult struct {
+	Path      string
+	XSDSchema []interface{}
+	Err       error
+}
+
+// ProcessFiles parses and generates every file in paths using a worker
+// pool of the given size (runtime.NumCPU() when size <= 0), sharing rc
+// across all workers. Each path's position in paths is passed to parse as
+// idx, so a parser that calls rc.Define(name, decl, idx) resolves a name
+// shared by two files the same way on every run, regardless of which
+// worker reaches it first. parse and generate are supplied by the caller
+// so ProcessFiles stays agnostic of any one language backend.
+func ProcessFiles(paths []string, size int, rc *RunContext, parse func(path string, idx int, rc *RunContext) ([]interface{}, error), generate func(path string, schema []interface{}, rc *RunContext) error) []FileResult {
+	if size <= 0 {
+		size = runtime.NumCPU()
+	}
+
+	jobs := make(chan FileJob, len(paths))
+	results := make([]FileResult, len(paths))
+
+	var wg sync.WaitGroup
+	for w := 0; w < size; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				schema, err := parse(job.Path, job.Index, rc)
+				if err == nil {
+					err = generate(job.Path, schema, rc)
+				}
+				results[job.Index] = FileResult{Path: job.Path, XSDSchema: schema, Err: err}
+			}
+		}()
+	}
+
+	for i, p := range paths {
+		jobs <- FileJob{Path: p, Index: i}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// Drive walks path with GetFileList and runs parse/generate over the
+// resulting files through ProcessFiles, so a directory of XSDs is parsed
+// and generated concurrently instead of GetFileList's result being handed
+// to a serial loop. GetFileList only ever returns regular files, but
+// Drive still de-duplicates paths before dispatch: ProcessFiles indexes
+// results by each path's position in the list, so a repeated path would
+// otherwise overwrite an earlier job's result with a later one's.
+func Drive(path string, size int, rc *RunContext, parse func(path string, idx int, rc *RunContext) ([]interface{}, error), generate func(path string, schema []interface{}, rc *RunContext) error) ([]FileResult, error) {
+	files, err := GetFileList(path)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(files))
+	unique := files[:0]
+	for _, f := range files {
+		if seen[f] {
+			continue
+		}
+		seen[f] = true
+		unique = append(unique, f)
+	}
+
+	return ProcessFiles(unique, size, rc, parse, generate), nil
+}
+
+// writeFileAtomic writes data to path by first writing to a temp file in
+// the same directory and renaming it into place, so a generator writing
+// output concurrently with other workers never leaves a reader with a
+// partially-written file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := ioutil.TempFile(dir, ".xgen-tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,110 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package xgen
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRunContextDefineKeepsLowestIndex asserts that Define resolves a name
+// shared by two callers to the lowest idx's declaration regardless of the
+// order the calls actually arrive in, matching input order rather than
+// goroutine scheduling order.
+func TestRunContextDefineKeepsLowestIndex(t *testing.T) {
+	rc := NewRunContext()
+
+	rc.Define("Document", "from-file-3", 3)
+	rc.Define("Document", "from-file-1", 1)
+	rc.Define("Document", "from-file-2", 2)
+
+	decl, ok := rc.Lookup("Document")
+	if !ok {
+		t.Fatal("Lookup(\"Document\") = not found, want found")
+	}
+	if decl != "from-file-1" {
+		t.Errorf("Lookup(\"Document\") = %v, want from-file-1 (lowest idx wins)", decl)
+	}
+}
+
+// TestDriveProcessesEachFileOnce asserts that Drive over a directory of two
+// files yields exactly one result and one parse call per file, and no
+// result for the directory itself.
+func TestDriveProcessesEachFileOnce(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen-drive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"a.xsd", "b.xsd"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("<xs:schema/>"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var parsed []string
+	parse := func(path string, idx int, rc *RunContext) ([]interface{}, error) {
+		parsed = append(parsed, path)
+		return nil, nil
+	}
+	generate := func(path string, schema []interface{}, rc *RunContext) error { return nil }
+
+	results, err := Drive(dir, 1, NewRunContext(), parse, generate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("len(results) = %d, want 2 (one per unique file, no directory entry)", len(results))
+	}
+	if len(parsed) != 2 {
+		t.Fatalf("parse called %d times, want 2", len(parsed))
+	}
+	for _, r := range results {
+		if r.Path == dir {
+			t.Errorf("results contains the directory itself: %+v", r)
+		}
+	}
+}
+
+// TestGetFileListNoDuplicateDirEntry asserts GetFileList on a directory
+// returns each regular file exactly once and never the directory path
+// itself, fixing a double-append that used to hand a directory fd to
+// parsers expecting an XSD file.
+func TestGetFileListNoDuplicateDirEntry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen-filelist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	want := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		name := filepath.Join(dir, fmt.Sprintf("schema-%d.xsd", i))
+		if err := ioutil.WriteFile(name, []byte("<xs:schema/>"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		want[name] = true
+	}
+
+	files, err := GetFileList(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(want) {
+		t.Fatalf("GetFileList returned %d entries, want %d: %v", len(files), len(want), files)
+	}
+	for _, f := range files {
+		if f == dir {
+			t.Errorf("GetFileList included the directory itself: %v", files)
+		}
+		if !want[f] {
+			t.Errorf("GetFileList returned unexpected entry %q", f)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// schemaNode adapts a SimpleType/ComplexType/Element/Attribute declaration
+// to a minimal tree shape so it can be addressed by the attribute-predicate
+// selector subset described in xpath.go, e.g. //complexType[@name='Document']
+// or //element[starts-with(@name,'pacs.008')].
+type schemaNode struct {
+	kind string // "simpleType", "complexType", "element", "attribute"
+	name string
+	decl interface{}
+}
+
+func schemaNodesOf(XSDSchema []interface{}) []schemaNode {
+	nodes := make([]schemaNode, 0, len(XSDSchema))
+	for _, ele := range XSDSchema {
+		switch v := ele.(type) {
+		case *SimpleType:
+			nodes = append(nodes, schemaNode{kind: "simpleType", name: v.Name, decl: v})
+		case *ComplexType:
+			nodes = append(nodes, schemaNode{kind: "complexType", name: v.Name, decl: v})
+		case *Element:
+			nodes = append(nodes, schemaNode{kind: "element", name: v.Name, decl: v})
+		case *Attribute:
+			nodes = append(nodes, schemaNode{kind: "attribute", name: v.Name, decl: v})
+		}
+	}
+	return nodes
+}
+
+// Selector is a single schema-selection criterion: either the
+// attribute-predicate selector described in xpath.go, evaluated against
+// the schemaNode tree, or a glob pattern matched against declaration names
+// (e.g. "pacs.008.*"). A CLI front end can collect repeated -select flags
+// into a []Selector and pass them straight to Filter.
+type Selector string
+
+// isGlob reports whether s looks like a glob rather than an xpath.go
+// selector expression - those always begin with "/" or "//".
+func (s Selector) isGlob() bool {
+	return !strings.HasPrefix(string(s), "/")
+}
+
+// nodeKey identifies a schema declaration by kind and name. XSD keeps
+// elements/attributes (the instance namespace) and simpleType/complexType
+// (the type namespace) separate, so "element name=Document" and
+// "complexType name=Document" can legitimately coexist; keying solely by
+// name would collapse them into one map slot and let whichever was walked
+// last silently win.
+type nodeKey struct {
+	kind string
+	name string
+}
+
+// Filter prunes XSDSchema down to the declarations matched by selectors
+// plus all of their transitive type dependencies (chased via Base, Ref, and
+// element Type), so a generator driven by the result only emits used types.
+func Filter(XSDSchema []interface{}, selectors ...Selector) ([]interface{}, error) {
+	nodes := schemaNodesOf(XSDSchema)
+	byKind := make(map[string]map[string]interface{}, 4)
+	for _, n := range nodes {
+		if byKind[n.kind] == nil {
+			byKind[n.kind] = make(map[string]interface{})
+		}
+		byKind[n.kind][n.name] = n.decl
+	}
+
+	matched := make(map[nodeKey]bool)
+	for _, sel := range selectors {
+		if sel.isGlob() {
+			matchGlob(nodes, string(sel), matched)
+			continue
+		}
+		if err := matchXPath(nodes, string(sel), matched); err != nil {
+			return nil, err
+		}
+	}
+
+	keep := make(map[nodeKey]bool, len(matched))
+	for key := range matched {
+		chaseDependencies(key, byKind, keep)
+	}
+
+	var pruned []interface{}
+	for _, ele := range XSDSchema {
+		if key, ok := declKey(ele); ok && keep[key] {
+			pruned = append(pruned, ele)
+		}
+	}
+	return pruned, nil
+}
+
+func matchGlob(nodes []schemaNode, pattern string, matched map[nodeKey]bool) {
+	for _, n := range nodes {
+		if ok, _ := filepath.Match(pattern, n.name); ok {
+			matched[nodeKey{kind: n.kind, name: n.name}] = true
+		}
+	}
+}
+
+// chaseDependencies walks the type graph reachable from key, following
+// SimpleType.Base, ComplexType.Base, Attribute.Ref/Type, and Element.Ref/Type,
+// recording every (kind, name) that must be emitted for key to be
+// self-contained.
+func chaseDependencies(key nodeKey, byKind map[string]map[string]interface{}, keep map[nodeKey]bool) {
+	if keep[key] {
+		return
+	}
+	keep[key] = true
+
+	decl, ok := byKind[key.kind][key.name]
+	if !ok {
+		return
+	}
+	for _, dep := range dependsOn(decl) {
+		name := trimNSPrefix(dep.name)
+		if dep.kind == "" {
+			chaseTypeRef(name, byKind, keep)
+			continue
+		}
+		if _, ok := byKind[dep.kind][name]; ok {
+			chaseDependencies(nodeKey{kind: dep.kind, name: name}, byKind, keep)
+		}
+	}
+}
+
+// chaseTypeRef follows a Base/Type reference. Those always name a
+// simpleType or complexType - the two kinds that share a single XSD type
+// namespace, so at most one of them actually holds depName.
+func chaseTypeRef(depName string, byKind map[string]map[string]interface{}, keep map[nodeKey]bool) {
+	for _, kind := range [...]string{"complexType", "simpleType"} {
+		if _, ok := byKind[kind][depName]; ok {
+			chaseDependencies(nodeKey{kind: kind, name: depName}, byKind, keep)
+			return
+		}
+	}
+}
+
+// dep names a single transitive reference from a declaration. A Base/Type
+// reference names a simpleType or complexType and is resolved by
+// chaseTypeRef (kind left blank, since the two type kinds share one XSD
+// namespace); a Ref reference names a *global* element or attribute
+// declaration directly and must stay within that exact kind - "ref" and
+// "type" are mutually exclusive on an XSD element/attribute, but only one
+// of them names a type.
+type dep struct {
+	name string
+	kind string
+}
+
+// dependsOn reports decl's direct dependencies: xs:complexType/simpleType
+// track their Base; xs:element/xs:attribute (both standalone and nested
+// inside a complexType's attributes/sequence/choice) track whichever of
+// Ref or Type is actually set, since a ref-based declaration (the common
+// ISO 20022 pattern of referencing a global element instead of declaring
+// an inline type) has no Type of its own to chase.
+func dependsOn(decl interface{}) []dep {
+	var deps []dep
+	switch v := decl.(type) {
+	case *SimpleType:
+		if v.Base != "" {
+			deps = append(deps, dep{name: v.Base})
+		}
+	case *ComplexType:
+		if v.Base != "" {
+			deps = append(deps, dep{name: v.Base})
+		}
+		for _, attr := range v.Attributes {
+			deps = append(deps, refOrType(attr.Ref, attr.Type, "attribute"))
+		}
+		for _, elem := range v.Sequence {
+			deps = append(deps, refOrType(elem.Ref, elem.Type, "element"))
+		}
+		for _, elem := range v.Choice {
+			deps = append(deps, refOrType(elem.Ref, elem.Type, "element"))
+		}
+	case *Element:
+		deps = append(deps, refOrType(v.Ref, v.Type, "element"))
+	case *Attribute:
+		deps = append(deps, refOrType(v.Ref, v.Type, "attribute"))
+	}
+
+	var filtered []dep
+	for _, d := range deps {
+		if d.name != "" {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
+
+// refOrType returns the dep for an element/attribute's Ref when set,
+// otherwise its Type; the two are mutually exclusive in XSD.
+func refOrType(ref, typ, refKind string) dep {
+	if ref != "" {
+		return dep{name: ref, kind: refKind}
+	}
+	return dep{name: typ}
+}
+
+func declKey(ele interface{}) (nodeKey, bool) {
+	switch v := ele.(type) {
+	case *SimpleType:
+		return nodeKey{kind: "simpleType", name: v.Name}, true
+	case *ComplexType:
+		return nodeKey{kind: "complexType", name: v.Name}, true
+	case *Element:
+		return nodeKey{kind: "element", name: v.Name}, true
+	case *Attribute:
+		return nodeKey{kind: "attribute", name: v.Name}, true
+	default:
+		return nodeKey{}, false
+	}
+}
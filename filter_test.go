@@ -0,0 +1,102 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package xgen
+
+import "testing"
+
+// TestFilterDisambiguatesSameNameDifferentKind guards against regressing to
+// a single name-keyed map: an element and a complexType sharing the name
+// "Document" are distinct declarations (elements and types live in separate
+// XSD namespaces), so selecting the complexType must walk its own
+// Attributes/Sequence dependencies, not the unrelated element's Type.
+func TestFilterDisambiguatesSameNameDifferentKind(t *testing.T) {
+	schema := []interface{}{
+		&Element{Name: "Document", Type: "OtherElementType"},
+		&ComplexType{Name: "Document", Attributes: []*Attribute{{Name: "Id", Type: "IdType"}}},
+		&SimpleType{Name: "OtherElementType", Base: "string"},
+		&SimpleType{Name: "IdType", Base: "string"},
+	}
+
+	pruned, err := Filter(schema, Selector("//complexType[@name='Document']"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotNames []string
+	for _, ele := range pruned {
+		key, ok := declKey(ele)
+		if !ok {
+			continue
+		}
+		gotNames = append(gotNames, key.kind+":"+key.name)
+	}
+
+	want := map[string]bool{"complexType:Document": true, "simpleType:IdType": true}
+	if len(gotNames) != len(want) {
+		t.Fatalf("Filter() kept %v, want exactly %v", gotNames, want)
+	}
+	for _, n := range gotNames {
+		if !want[n] {
+			t.Errorf("Filter() unexpectedly kept %s", n)
+		}
+	}
+	for _, n := range gotNames {
+		if n == "element:Document" || n == "simpleType:OtherElementType" {
+			t.Errorf("Filter() pulled in the unrelated element:Document's dependency %s", n)
+		}
+	}
+}
+
+// TestFilterChasesElementRef guards against a regression where
+// dependsOn only ever read Type, silently dropping any element declared
+// with xs:element ref="..." instead of an inline type - the common ISO
+// 20022 pattern of referencing a global element.
+func TestFilterChasesElementRef(t *testing.T) {
+	schema := []interface{}{
+		&ComplexType{Name: "Document", Sequence: []*Element{{Ref: "GrpHdr"}}},
+		&Element{Name: "GrpHdr", Type: "GroupHeaderType"},
+		&SimpleType{Name: "GroupHeaderType", Base: "string"},
+	}
+
+	pruned, err := Filter(schema, Selector("//complexType[@name='Document']"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kept := map[string]bool{}
+	for _, ele := range pruned {
+		key, ok := declKey(ele)
+		if !ok {
+			continue
+		}
+		kept[key.kind+":"+key.name] = true
+	}
+
+	want := []string{"complexType:Document", "element:GrpHdr", "simpleType:GroupHeaderType"}
+	for _, n := range want {
+		if !kept[n] {
+			t.Errorf("Filter() dropped %s reached only via xs:element ref", n)
+		}
+	}
+}
+
+func TestFilterGlob(t *testing.T) {
+	schema := []interface{}{
+		&Element{Name: "pacs.008.001.08", Type: "string"},
+		&Element{Name: "pacs.009.001.08", Type: "string"},
+		&Element{Name: "camt.053.001.08", Type: "string"},
+	}
+
+	pruned, err := Filter(schema, Selector("pacs.008.*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned) != 1 {
+		t.Fatalf("Filter() kept %d declarations, want 1", len(pruned))
+	}
+	if key, _ := declKey(pruned[0]); key.name != "pacs.008.001.08" {
+		t.Fatalf("Filter() kept %q, want pacs.008.001.08", key.name)
+	}
+}
@@ -0,0 +1,310 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// jsonSchemaBuildInTypes maps XSD primitive names to their JSON Schema
+// `type` (and, where relevant, `format`/`contentEncoding`) representation.
+// https://json-schema.org/draft/2020-12/json-schema-validation
+var jsonSchemaBuildInTypes = map[string]map[string]string{
+	"anyType":            {"type": "string"},
+	"ENTITIES":           {"type": "array"},
+	"ENTITY":             {"type": "string"},
+	"ID":                 {"type": "string"},
+	"IDREF":              {"type": "string"},
+	"IDREFS":             {"type": "array"},
+	"NCName":             {"type": "string"},
+	"NMTOKEN":            {"type": "string"},
+	"NMTOKENS":           {"type": "array"},
+	"NOTATION":           {"type": "array"},
+	"Name":               {"type": "string"},
+	"QName":              {"type": "string"},
+	"anyURI":             {"type": "string", "format": "uri"},
+	"base64Binary":       {"type": "string", "contentEncoding": "base64"},
+	"boolean":            {"type": "boolean"},
+	"byte":               {"type": "integer"},
+	"date":               {"type": "string", "format": "date"},
+	"dateTime":           {"type": "string", "format": "date-time"},
+	"decimal":            {"type": "number"},
+	"double":             {"type": "number"},
+	"duration":           {"type": "string", "format": "duration"},
+	"float":              {"type": "number"},
+	"gDay":               {"type": "string"},
+	"gMonth":             {"type": "string"},
+	"gMonthDay":          {"type": "string"},
+	"gYear":              {"type": "string"},
+	"gYearMonth":         {"type": "string"},
+	"hexBinary":          {"type": "string", "contentEncoding": "base16"},
+	"int":                {"type": "integer"},
+	"integer":            {"type": "integer"},
+	"language":           {"type": "string"},
+	"long":               {"type": "integer"},
+	"negativeInteger":    {"type": "integer"},
+	"nonNegativeInteger": {"type": "integer"},
+	"normalizedString":   {"type": "string"},
+	"nonPositiveInteger": {"type": "integer"},
+	"positiveInteger":    {"type": "integer"},
+	"short":              {"type": "integer"},
+	"string":             {"type": "string"},
+	"time":               {"type": "string", "format": "time"},
+	"token":              {"type": "string"},
+	"unsignedByte":       {"type": "integer"},
+	"unsignedInt":        {"type": "integer"},
+	"unsignedLong":       {"type": "integer"},
+	"unsignedShort":      {"type": "integer"},
+}
+
+// jsonSchemaDraft identifies the JSON Schema dialect every generated
+// document declares via "$schema".
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema generates JSON Schema (draft 2020-12) documents from a parsed
+// XSDSchema. One schema document is produced per named complexType and
+// simpleType; Element and Attribute declarations are inlined as properties
+// of their enclosing type. Every document bundles every named type's body
+// under "definitions" so the "$ref"s it emits always resolve, regardless of
+// which document a validator loads first.
+type JSONSchema struct {
+	XSDSchema  []interface{}
+	Lang       string
+	Schemas    map[string]string
+	Naming     NamingStrategy
+	nameToDecl map[string]interface{}
+}
+
+// NewJSONSchema returns a JSONSchema generator for the given parsed schema,
+// defaulting Naming to JSONSchemaNamingStrategy.
+func NewJSONSchema(XSDSchema []interface{}) *JSONSchema {
+	return &JSONSchema{
+		XSDSchema: XSDSchema,
+		Lang:      "JSONSchema",
+		Schemas:   make(map[string]string),
+		Naming:    defaultNamingStrategy("JSONSchema"),
+	}
+}
+
+// Generate walks the XSDSchema and renders one self-contained JSON Schema
+// document per named type into g.Schemas, keyed by the type's generated
+// name.
+func (g *JSONSchema) Generate() error {
+	g.indexDecls()
+
+	bodies := make(map[string]string, len(g.XSDSchema))
+	for _, ele := range g.XSDSchema {
+		switch v := ele.(type) {
+		case *ComplexType:
+			bodies[g.Naming.TypeName(v.Name)] = g.genComplexType(v)
+		case *SimpleType:
+			bodies[g.Naming.TypeName(v.Name)] = g.genSimpleType(v)
+		}
+	}
+
+	names := make([]string, 0, len(bodies))
+	for name := range bodies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var defs []string
+	for _, name := range names {
+		defs = append(defs, fmt.Sprintf("%q: %s", name, bodies[name]))
+	}
+	definitions := fmt.Sprintf("{%s}", strings.Join(defs, ", "))
+
+	for _, name := range names {
+		g.Schemas[name] = fmt.Sprintf(
+			"{\n  \"$schema\": %q,\n  \"definitions\": %s,\n  \"$ref\": \"#/definitions/%s\"\n}",
+			jsonSchemaDraft, definitions, name,
+		)
+	}
+	return nil
+}
+
+func (g *JSONSchema) indexDecls() {
+	g.nameToDecl = make(map[string]interface{})
+	for _, ele := range g.XSDSchema {
+		switch v := ele.(type) {
+		case *ComplexType:
+			g.nameToDecl[v.Name] = v
+		case *SimpleType:
+			g.nameToDecl[v.Name] = v
+		}
+	}
+}
+
+func (g *JSONSchema) genComplexType(ct *ComplexType) string {
+	var props []string
+	var required []string
+
+	for _, attr := range ct.Attributes {
+		key := g.Naming.FieldName(attr.Name, ct.Name)
+		props = append(props, fmt.Sprintf("%q: %s", key, g.typeRef(attr.Type)))
+		if !attr.Optional {
+			required = append(required, fmt.Sprintf("%q", key))
+		}
+	}
+
+	for _, elem := range ct.Sequence {
+		key := g.Naming.FieldName(elem.Name, ct.Name)
+		schema := g.elementRef(elem)
+		if elem.Plural {
+			schema = fmt.Sprintf("{\"type\": \"array\", \"items\": %s}", schema)
+		}
+		props = append(props, fmt.Sprintf("%q: %s", key, schema))
+		if !elem.Optional {
+			required = append(required, fmt.Sprintf("%q", key))
+		}
+	}
+
+	for _, member := range ct.Choice {
+		key := g.Naming.FieldName(member.Name, ct.Name)
+		props = append(props, fmt.Sprintf("%q: %s", key, g.elementRef(member)))
+	}
+
+	sort.Strings(props)
+	sort.Strings(required)
+
+	var buf strings.Builder
+	buf.WriteString("{\n  \"type\": \"object\",\n")
+	buf.WriteString(fmt.Sprintf("  \"properties\": {%s},\n", strings.Join(props, ", ")))
+	buf.WriteString(fmt.Sprintf("  \"required\": [%s]", strings.Join(required, ", ")))
+	if len(ct.Choice) > 0 {
+		buf.WriteString(fmt.Sprintf(",\n  \"oneOf\": [%s]", g.choiceOneOf(ct)))
+	}
+	buf.WriteString("\n}")
+	return buf.String()
+}
+
+// choiceOneOf renders ct's xs:choice members as sibling subschemas, each
+// requiring exactly the one member's property, so "oneOf" enforces that
+// precisely one of the choice's elements is present. xs:choice has no
+// property of its own in the generated document - unlike the previous
+// synthetic "choice" property, which didn't correspond to any field in the
+// actual data and dropped the exactly-one-of-these semantics entirely.
+func (g *JSONSchema) choiceOneOf(ct *ComplexType) string {
+	var options []string
+	for _, member := range ct.Choice {
+		key := g.Naming.FieldName(member.Name, ct.Name)
+		options = append(options, fmt.Sprintf(
+			"{\"required\": [%q], \"properties\": {%q: %s}}", key, key, g.elementRef(member),
+		))
+	}
+	return strings.Join(options, ", ")
+}
+
+func (g *JSONSchema) genSimpleType(st *SimpleType) string {
+	if st.List {
+		return fmt.Sprintf("{\"type\": \"array\", \"items\": %s}", g.typeRef(st.Base))
+	}
+
+	keywords := g.restrictionKeywords(st)
+	base := g.typeRef(st.Base)
+	if keywords == "" {
+		return base
+	}
+	return fmt.Sprintf("{\"allOf\": [%s, {%s}]}", base, keywords)
+}
+
+// restrictionKeywords translates xs:restriction facets into their JSON
+// Schema validation keyword equivalents. fractionDigits and totalDigits are
+// independent facets - a totalDigits with no fractionDigits bounds only the
+// digit count, not the precision - so each gets its own explicit
+// has<Facet> guard, the same pattern minLength/maxLength already use,
+// rather than inferring one facet's presence from the other's value.
+func (g *JSONSchema) restrictionKeywords(st *SimpleType) string {
+	var kw []string
+	r := st.Restriction
+
+	if r.Pattern != nil {
+		kw = append(kw, fmt.Sprintf("\"pattern\": %q", r.Pattern.String()))
+	}
+	if len(r.Enum) > 0 {
+		var quoted []string
+		for _, e := range r.Enum {
+			quoted = append(quoted, fmt.Sprintf("%q", e))
+		}
+		kw = append(kw, fmt.Sprintf("\"enum\": [%s]", strings.Join(quoted, ", ")))
+	}
+	if r.hasMinLength {
+		kw = append(kw, fmt.Sprintf("\"minLength\": %d", r.MinLength))
+	}
+	if r.hasMaxLength {
+		kw = append(kw, fmt.Sprintf("\"maxLength\": %d", r.MaxLength))
+	}
+	if r.MinInclusive != "" {
+		kw = append(kw, fmt.Sprintf("\"minimum\": %s", r.MinInclusive))
+	}
+	if r.MaxInclusive != "" {
+		kw = append(kw, fmt.Sprintf("\"maximum\": %s", r.MaxInclusive))
+	}
+	if r.hasFractionDigits {
+		kw = append(kw, fmt.Sprintf("\"multipleOf\": 1e-%d", r.FractionDigits))
+	}
+	if r.hasTotalDigits && r.Pattern == nil {
+		fractionDigits := -1
+		if r.hasFractionDigits {
+			fractionDigits = r.FractionDigits
+		}
+		kw = append(kw, fmt.Sprintf("\"pattern\": %q", totalDigitsPattern(r.TotalDigits, fractionDigits)))
+	}
+
+	return strings.Join(kw, ", ")
+}
+
+// totalDigitsPattern renders a regex bounding a decimal to at most total
+// significant digits, the only way JSON Schema can express xs:totalDigits -
+// it has no native digit-count keyword. A fractionDigits of 0 or more
+// splits the digits into intDigits/fractionDigits; a negative one (no
+// xs:fractionDigits facet) admits any split between the integer and
+// fraction parts. Skipped when an explicit xs:pattern facet is already
+// present, since both translate to the same "pattern" keyword and the two
+// can't be combined into one regex.
+func totalDigitsPattern(totalDigits, fractionDigits int) string {
+	if fractionDigits < 0 {
+		alts := []string{fmt.Sprintf(`\d{1,%d}`, totalDigits)}
+		for frac := 1; frac < totalDigits; frac++ {
+			alts = append(alts, fmt.Sprintf(`\d{1,%d}\.\d{1,%d}`, totalDigits-frac, frac))
+		}
+		return fmt.Sprintf(`^-?(%s)$`, strings.Join(alts, "|"))
+	}
+	intDigits := totalDigits - fractionDigits
+	if fractionDigits <= 0 {
+		return fmt.Sprintf(`^-?\d{1,%d}$`, intDigits)
+	}
+	return fmt.Sprintf(`^-?\d{1,%d}(\.\d{1,%d})?$`, intDigits, fractionDigits)
+}
+
+// typeRef resolves name to either a JSON Schema `$ref` pointing at a
+// sibling type declared in the same schema, or an inline primitive
+// rendering when name is an XSD build-in type.
+func (g *JSONSchema) typeRef(name string) string {
+	name = trimNSPrefix(name)
+	if kw, ok := jsonSchemaBuildInTypes[name]; ok {
+		var parts []string
+		for _, k := range []string{"type", "format", "contentEncoding"} {
+			if v, ok := kw[k]; ok {
+				parts = append(parts, fmt.Sprintf("%q: %q", k, v))
+			}
+		}
+		return fmt.Sprintf("{%s}", strings.Join(parts, ", "))
+	}
+	if _, ok := g.nameToDecl[name]; ok {
+		return fmt.Sprintf("{\"$ref\": \"#/definitions/%s\"}", g.Naming.TypeName(name))
+	}
+	return "{\"type\": \"string\"}"
+}
+
+func (g *JSONSchema) elementRef(elem *Element) string {
+	return g.typeRef(elem.Type)
+}
@@ -0,0 +1,204 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package xgen
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestJSONSchemaGenerateBundlesDefinitions(t *testing.T) {
+	schema := []interface{}{
+		&ComplexType{
+			Name:       "Document",
+			Attributes: []*Attribute{{Name: "Id", Type: "IdType"}},
+		},
+		&SimpleType{Name: "IdType", Base: "string"},
+	}
+
+	g := NewJSONSchema(schema)
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, ok := g.Schemas["Document"]
+	if !ok {
+		t.Fatalf("Schemas missing \"Document\", got keys %v", keysOf(g.Schemas))
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(doc), &parsed); err != nil {
+		t.Fatalf("Document schema is not valid JSON: %v\n%s", err, doc)
+	}
+
+	if parsed["$schema"] != jsonSchemaDraft {
+		t.Errorf("$schema = %v, want %v", parsed["$schema"], jsonSchemaDraft)
+	}
+
+	defs, ok := parsed["definitions"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("definitions missing or not an object: %v", parsed["definitions"])
+	}
+	for _, want := range []string{"Document", "IdType"} {
+		if _, ok := defs[want]; !ok {
+			t.Errorf("definitions missing %q, got %v", want, keysOf2(defs))
+		}
+	}
+
+	if parsed["$ref"] != "#/definitions/Document" {
+		t.Errorf("$ref = %v, want #/definitions/Document", parsed["$ref"])
+	}
+}
+
+func TestJSONSchemaChoiceIsOneOfNotASyntheticProperty(t *testing.T) {
+	schema := []interface{}{
+		&ComplexType{
+			Name: "Payment",
+			Choice: []*Element{
+				{Name: "CdtTrfTxInf", Type: "string"},
+				{Name: "DrctDbtTxInf", Type: "string"},
+			},
+		},
+	}
+
+	g := NewJSONSchema(schema)
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	doc := g.Schemas["Payment"]
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(doc), &parsed); err != nil {
+		t.Fatalf("Payment schema is not valid JSON: %v\n%s", err, doc)
+	}
+
+	defs := parsed["definitions"].(map[string]interface{})
+	payment := defs["Payment"].(map[string]interface{})
+
+	props, _ := payment["properties"].(map[string]interface{})
+	if _, ok := props["choice"]; ok {
+		t.Error("properties still contains a synthetic \"choice\" key")
+	}
+	if _, ok := props["CdtTrfTxInf"]; !ok {
+		t.Error("properties missing CdtTrfTxInf")
+	}
+
+	oneOf, ok := payment["oneOf"].([]interface{})
+	if !ok || len(oneOf) != 2 {
+		t.Fatalf("oneOf = %v, want 2 entries", payment["oneOf"])
+	}
+}
+
+// TestRestrictionKeywordsFacets covers the facet-translation half of JSON
+// Schema generation: pattern, enum, minLength/maxLength, minInclusive, and
+// fractionDigits/totalDigits all need independent coverage since each is
+// gated by its own presence check (a has<Facet> flag for the length and
+// digit facets, a nil/empty check for the rest).
+func TestRestrictionKeywordsFacets(t *testing.T) {
+	g := NewJSONSchema(nil)
+
+	t.Run("pattern and enum", func(t *testing.T) {
+		st := &SimpleType{Restriction: Restriction{
+			Pattern: regexp.MustCompile(`[A-Z]{3}`),
+			Enum:    []string{"EUR", "USD"},
+		}}
+		kw := g.restrictionKeywords(st)
+		if !strings.Contains(kw, `"pattern": "[A-Z]{3}"`) {
+			t.Errorf("restrictionKeywords() = %q, missing pattern", kw)
+		}
+		if !strings.Contains(kw, `"enum": ["EUR", "USD"]`) {
+			t.Errorf("restrictionKeywords() = %q, missing enum", kw)
+		}
+	})
+
+	t.Run("minLength and maxLength", func(t *testing.T) {
+		st := &SimpleType{Restriction: Restriction{hasMinLength: true, MinLength: 1, hasMaxLength: true, MaxLength: 35}}
+		kw := g.restrictionKeywords(st)
+		if !strings.Contains(kw, `"minLength": 1`) || !strings.Contains(kw, `"maxLength": 35`) {
+			t.Errorf("restrictionKeywords() = %q, want minLength 1 and maxLength 35", kw)
+		}
+	})
+
+	t.Run("minInclusive and maxInclusive", func(t *testing.T) {
+		st := &SimpleType{Restriction: Restriction{MinInclusive: "0", MaxInclusive: "100"}}
+		kw := g.restrictionKeywords(st)
+		if !strings.Contains(kw, `"minimum": 0`) || !strings.Contains(kw, `"maximum": 100`) {
+			t.Errorf("restrictionKeywords() = %q, want minimum 0 and maximum 100", kw)
+		}
+	})
+
+	t.Run("fractionDigits alone does not imply multipleOf without the flag", func(t *testing.T) {
+		st := &SimpleType{Restriction: Restriction{FractionDigits: 2}}
+		kw := g.restrictionKeywords(st)
+		if strings.Contains(kw, "multipleOf") {
+			t.Errorf("restrictionKeywords() = %q, want no multipleOf when hasFractionDigits is false", kw)
+		}
+	})
+
+	t.Run("totalDigits bounds the digit count", func(t *testing.T) {
+		st := &SimpleType{Restriction: Restriction{hasTotalDigits: true, TotalDigits: 5}}
+		kw := g.restrictionKeywords(st)
+		pattern := extractJSONString(t, kw, "pattern")
+		re := regexp.MustCompile(pattern)
+		if re.MatchString("123456789.12") {
+			t.Errorf("totalDigits=5 pattern %q wrongly matches an 11-digit value", pattern)
+		}
+		if !re.MatchString("99999") {
+			t.Errorf("totalDigits=5 pattern %q wrongly rejects a 5-digit value", pattern)
+		}
+	})
+
+	t.Run("totalDigits with fractionDigits bounds integer and fraction parts", func(t *testing.T) {
+		st := &SimpleType{Restriction: Restriction{
+			hasTotalDigits: true, TotalDigits: 5,
+			hasFractionDigits: true, FractionDigits: 2,
+		}}
+		kw := g.restrictionKeywords(st)
+		if !strings.Contains(kw, `"multipleOf": 1e-2`) {
+			t.Errorf("restrictionKeywords() = %q, want multipleOf 1e-2", kw)
+		}
+		pattern := extractJSONString(t, kw, "pattern")
+		re := regexp.MustCompile(pattern)
+		if !re.MatchString("999.99") {
+			t.Errorf("totalDigits=5/fractionDigits=2 pattern %q wrongly rejects 999.99", pattern)
+		}
+		if re.MatchString("1999.99") {
+			t.Errorf("totalDigits=5/fractionDigits=2 pattern %q wrongly matches 1999.99 (6 digits)", pattern)
+		}
+	})
+}
+
+// extractJSONString pulls the string value of key out of a restrictionKeywords
+// fragment by wrapping it as a JSON object.
+func extractJSONString(t *testing.T, kw, key string) string {
+	t.Helper()
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte("{"+kw+"}"), &parsed); err != nil {
+		t.Fatalf("restrictionKeywords() produced invalid JSON fragment %q: %v", kw, err)
+	}
+	v, ok := parsed[key].(string)
+	if !ok {
+		t.Fatalf("restrictionKeywords() = %q, missing string key %q", kw, key)
+	}
+	return v
+}
+
+func keysOf(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func keysOf2(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package xgen
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestRustStructRendersFieldsWithAttributes covers the struct-field path
+// this file's helpers were written for but, until now, had no caller
+// assembling: derive line, Option<>/Vec<> wrapping, serde rename, and
+// validator attributes on a single generated struct.
+func TestRustStructRendersFieldsWithAttributes(t *testing.T) {
+	nameToDecl := rustIndexDecls([]interface{}{
+		&SimpleType{Name: "IdType", Base: "string", Restriction: Restriction{hasMaxLength: true, MaxLength: 35}},
+	})
+	ct := &ComplexType{
+		Name:       "Document",
+		Attributes: []*Attribute{{Name: "Id", Type: "IdType"}},
+		Sequence: []*Element{
+			{Name: "Amount", Type: "decimal", Optional: true},
+			{Name: "Note", Type: "string", Plural: true},
+		},
+	}
+
+	out := rustStruct(RustNamingStrategy{}, ct, nameToDecl, map[RustFeature]bool{RustFeatureSerde: true, RustFeatureValidator: true})
+
+	if !strings.Contains(out, "#[derive(Debug, Clone, Serialize, Deserialize, Validate)]") {
+		t.Errorf("rustStruct() missing expected derive line:\n%s", out)
+	}
+	if !strings.Contains(out, "pub struct Document {") {
+		t.Errorf("rustStruct() missing struct header:\n%s", out)
+	}
+	if !strings.Contains(out, "#[serde(rename = \"Id\")]\n    #[validate(length(max = 35))]\n    pub id: IdType,") {
+		t.Errorf("rustStruct() field id missing expected attributes/type:\n%s", out)
+	}
+	if !strings.Contains(out, "pub amount: Option<f64>,") {
+		t.Errorf("rustStruct() optional field not wrapped in Option<>:\n%s", out)
+	}
+	if !strings.Contains(out, "pub note: Vec<String>,") {
+		t.Errorf("rustStruct() plural field not wrapped in Vec<>:\n%s", out)
+	}
+}
+
+func TestRustEnumVariantsUsesPascalCase(t *testing.T) {
+	out := rustEnumVariants(RustNamingStrategy{}, "PaymentStatus", []string{"ACCEPTED-VALUE", "REJECTED"}, map[RustFeature]bool{})
+	if strings.Contains(out, "Accepted_value") {
+		t.Errorf("rustEnumVariants() kept snake_case in a variant name:\n%s", out)
+	}
+	if !strings.Contains(out, "AcceptedValue,") {
+		t.Errorf("rustEnumVariants() missing PascalCase variant AcceptedValue:\n%s", out)
+	}
+	if !strings.Contains(out, "Rejected,") {
+		t.Errorf("rustEnumVariants() missing variant Rejected:\n%s", out)
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v2"
+)
+
+// NamingStrategy turns XSD QNames into the identifiers a generated language
+// target expects, so the generator itself stays free of any one language's
+// casing convention. Each CodeGenerator selects the default implementation
+// matching its target language; callers needing stable names across
+// regenerations can wrap one in an OverrideNamingStrategy.
+type NamingStrategy interface {
+	// TypeName returns the identifier used for a named simpleType or
+	// complexType.
+	TypeName(xsdName string) string
+	// FieldName returns the identifier used for an element or attribute
+	// declared inside parent.
+	FieldName(xsdName string, parent string) string
+	// EnumVariant returns the identifier used for one xs:enumeration value
+	// of the enclosing type typeName.
+	EnumVariant(typeName string, xsdValue string) string
+	// Disambiguate returns name, or name suffixed to be unique against
+	// taken, incrementing taken[name] as a side effect - mirroring the
+	// old package-level fieldNameCount bookkeeping but scoped per-caller.
+	Disambiguate(name string, taken map[string]int) string
+}
+
+func disambiguate(name string, taken map[string]int) string {
+	taken[name]++
+	if n := taken[name]; n > 1 {
+		return fmt.Sprintf("%s%d", name, n)
+	}
+	return name
+}
+
+// GoNamingStrategy produces PascalCase exported type and field names,
+// matching idiomatic Go.
+type GoNamingStrategy struct{}
+
+func (GoNamingStrategy) TypeName(xsdName string) string { return MakeFirstUpperCase(xsdName) }
+func (GoNamingStrategy) FieldName(xsdName string, parent string) string {
+	return MakeFirstUpperCase(xsdName)
+}
+func (GoNamingStrategy) EnumVariant(typeName string, xsdValue string) string {
+	return MakeFirstUpperCase(xsdValue)
+}
+func (GoNamingStrategy) Disambiguate(name string, taken map[string]int) string {
+	return disambiguate(name, taken)
+}
+
+// TypeScriptNamingStrategy produces PascalCase types and camelCase fields,
+// matching idiomatic TypeScript.
+type TypeScriptNamingStrategy struct{}
+
+func (TypeScriptNamingStrategy) TypeName(xsdName string) string { return MakeFirstUpperCase(xsdName) }
+func (TypeScriptNamingStrategy) FieldName(xsdName string, parent string) string {
+	return lowerFirst(xsdName)
+}
+func (TypeScriptNamingStrategy) EnumVariant(typeName string, xsdValue string) string {
+	return MakeFirstUpperCase(xsdValue)
+}
+func (TypeScriptNamingStrategy) Disambiguate(name string, taken map[string]int) string {
+	return disambiguate(name, taken)
+}
+
+// JavaNamingStrategy produces PascalCase types and camelCase methods,
+// matching idiomatic Java.
+type JavaNamingStrategy struct{}
+
+func (JavaNamingStrategy) TypeName(xsdName string) string { return MakeFirstUpperCase(xsdName) }
+func (JavaNamingStrategy) FieldName(xsdName string, parent string) string {
+	return lowerFirst(xsdName)
+}
+func (JavaNamingStrategy) EnumVariant(typeName string, xsdValue string) string {
+	return strings.ToUpper(ToSnakeCase(xsdValue))
+}
+func (JavaNamingStrategy) Disambiguate(name string, taken map[string]int) string {
+	return disambiguate(name, taken)
+}
+
+// RustNamingStrategy produces PascalCase types and snake_case fields,
+// matching idiomatic Rust.
+type RustNamingStrategy struct{}
+
+func (RustNamingStrategy) TypeName(xsdName string) string { return MakeFirstUpperCase(xsdName) }
+func (RustNamingStrategy) FieldName(xsdName string, parent string) string {
+	return ToSnakeCase(xsdName)
+}
+func (RustNamingStrategy) EnumVariant(typeName string, xsdValue string) string {
+	return rustPascalCase(xsdValue)
+}
+func (RustNamingStrategy) Disambiguate(name string, taken map[string]int) string {
+	return disambiguate(name, taken)
+}
+
+// rustPascalCase converts an XSD enumeration value such as "ACCEPTED-VALUE"
+// into the PascalCase identifier a Rust enum variant expects
+// (AcceptedValue). MakeFirstUpperCase only upper-cases the first rune, so
+// applying it to a snake_cased string would leave the underscores in place
+// (Accepted_value); this instead runs the value through ToSnakeCase to
+// normalize every word boundary to "_", then upper-cases the first rune of
+// each segment and drops the separators.
+func rustPascalCase(s string) string {
+	var buf strings.Builder
+	upperNext := true
+	for _, r := range ToSnakeCase(s) {
+		if r == '_' {
+			upperNext = true
+			continue
+		}
+		if upperNext {
+			r = unicode.ToUpper(r)
+			upperNext = false
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+// CNamingStrategy keeps C's existing convention: snake_case types and
+// fields, since C has no casing convention of its own to defer to.
+type CNamingStrategy struct{}
+
+func (CNamingStrategy) TypeName(xsdName string) string { return ToSnakeCase(xsdName) }
+func (CNamingStrategy) FieldName(xsdName string, parent string) string {
+	return ToSnakeCase(xsdName)
+}
+func (CNamingStrategy) EnumVariant(typeName string, xsdValue string) string {
+	return strings.ToUpper(ToSnakeCase(xsdValue))
+}
+func (CNamingStrategy) Disambiguate(name string, taken map[string]int) string {
+	return disambiguate(name, taken)
+}
+
+// JSONSchemaNamingStrategy keeps every identifier exactly as it appears in
+// the XSD. A JSON Schema document here validates JSON produced by
+// converting XML to JSON with tag names preserved, so casing property keys
+// to a target-language convention would make them stop matching the wire
+// format they're meant to validate.
+type JSONSchemaNamingStrategy struct{}
+
+func (JSONSchemaNamingStrategy) TypeName(xsdName string) string { return xsdName }
+func (JSONSchemaNamingStrategy) FieldName(xsdName string, parent string) string {
+	return xsdName
+}
+func (JSONSchemaNamingStrategy) EnumVariant(typeName string, xsdValue string) string {
+	return xsdValue
+}
+func (JSONSchemaNamingStrategy) Disambiguate(name string, taken map[string]int) string {
+	return disambiguate(name, taken)
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// defaultNamingStrategy returns the NamingStrategy a CodeGenerator should
+// use by default for lang, matching getBuildInTypeByLang's language names.
+func defaultNamingStrategy(lang string) NamingStrategy {
+	switch lang {
+	case "Go":
+		return GoNamingStrategy{}
+	case "TypeScript":
+		return TypeScriptNamingStrategy{}
+	case "Java":
+		return JavaNamingStrategy{}
+	case "Rust":
+		return RustNamingStrategy{}
+	case "C":
+		return CNamingStrategy{}
+	case "JSONSchema":
+		return JSONSchemaNamingStrategy{}
+	default:
+		return GoNamingStrategy{}
+	}
+}
+
+// NameOverrides maps specific XSD declaration names to explicit identifiers
+// per language, loaded from a user-supplied YAML or JSON file, so downstream
+// projects can pin stable names across regenerations even as the upstream
+// XSD evolves. Types are keyed by their bare name and fields by
+// "Parent.FieldName" - the same bare names TypeName/FieldName are called
+// with, with no namespace or file qualifier, since callers never have one
+// to offer. A name shared by two unrelated schemas gets the same override
+// in both. File format:
+//
+//	types:
+//	  "Document": { Go: CustomDocument, Rust: CustomDocument }
+//	fields:
+//	  "Document.GrpHdr": { Go: GroupHeader }
+type NameOverrides struct {
+	Types  map[string]map[string]string `json:"types" yaml:"types"`
+	Fields map[string]map[string]string `json:"fields" yaml:"fields"`
+}
+
+// LoadNameOverrides reads a YAML or JSON overrides file, selecting the
+// decoder by file extension (.json vs. .yaml/.yml).
+func LoadNameOverrides(path string) (*NameOverrides, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := &NameOverrides{}
+	if strings.HasSuffix(path, ".json") {
+		err = json.Unmarshal(data, overrides)
+	} else {
+		err = yaml.Unmarshal(data, overrides)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("xgen: parsing name overrides %s: %w", path, err)
+	}
+	return overrides, nil
+}
+
+// OverrideNamingStrategy wraps a NamingStrategy, substituting any name
+// pinned in overrides for the given lang before falling back to it.
+type OverrideNamingStrategy struct {
+	NamingStrategy
+	Lang      string
+	Overrides *NameOverrides
+}
+
+// NewOverrideNamingStrategy returns strategy wrapped so xsdName lookups in
+// overrides win over strategy's own derivation.
+func NewOverrideNamingStrategy(strategy NamingStrategy, lang string, overrides *NameOverrides) *OverrideNamingStrategy {
+	return &OverrideNamingStrategy{NamingStrategy: strategy, Lang: lang, Overrides: overrides}
+}
+
+func (o *OverrideNamingStrategy) TypeName(xsdName string) string {
+	if o.Overrides != nil {
+		if names, ok := o.Overrides.Types[xsdName]; ok {
+			if name, ok := names[o.Lang]; ok {
+				return name
+			}
+		}
+	}
+	return o.NamingStrategy.TypeName(xsdName)
+}
+
+func (o *OverrideNamingStrategy) FieldName(xsdName string, parent string) string {
+	if o.Overrides != nil {
+		key := parent + "." + xsdName
+		if names, ok := o.Overrides.Fields[key]; ok {
+			if name, ok := names[o.Lang]; ok {
+				return name
+			}
+		}
+	}
+	return o.NamingStrategy.FieldName(xsdName, parent)
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package xgen
+
+import "testing"
+
+func TestRustPascalCase(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"ACCEPTED-VALUE", "AcceptedValue"},
+		{"ACCEPTED_VALUE", "AcceptedValue"},
+		{"someValue", "SomeValue"},
+		{"Pending", "Pending"},
+		{"a", "A"},
+	}
+
+	for _, tt := range tests {
+		if got := rustPascalCase(tt.in); got != tt.want {
+			t.Errorf("rustPascalCase(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRustNamingStrategyEnumVariant(t *testing.T) {
+	if got := (RustNamingStrategy{}).EnumVariant("Status", "ACCEPTED-VALUE"); got != "AcceptedValue" {
+		t.Errorf("EnumVariant() = %q, want AcceptedValue", got)
+	}
+}
+
+func TestJSONSchemaNamingStrategyIsIdentity(t *testing.T) {
+	s := JSONSchemaNamingStrategy{}
+	if got := s.TypeName("GrpHdr"); got != "GrpHdr" {
+		t.Errorf("TypeName() = %q, want GrpHdr unchanged", got)
+	}
+	if got := s.FieldName("GrpHdr", "Document"); got != "GrpHdr" {
+		t.Errorf("FieldName() = %q, want GrpHdr unchanged", got)
+	}
+}
+
+func TestDefaultNamingStrategy(t *testing.T) {
+	tests := []struct {
+		lang string
+		want NamingStrategy
+	}{
+		{"Go", GoNamingStrategy{}},
+		{"TypeScript", TypeScriptNamingStrategy{}},
+		{"Java", JavaNamingStrategy{}},
+		{"Rust", RustNamingStrategy{}},
+		{"C", CNamingStrategy{}},
+		{"JSONSchema", JSONSchemaNamingStrategy{}},
+		{"unknown-lang", GoNamingStrategy{}},
+	}
+
+	for _, tt := range tests {
+		if got := defaultNamingStrategy(tt.lang); got != tt.want {
+			t.Errorf("defaultNamingStrategy(%q) = %#v, want %#v", tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestOverrideNamingStrategyPinsName(t *testing.T) {
+	overrides := &NameOverrides{
+		Types:  map[string]map[string]string{"Document": {"Go": "CustomDocument"}},
+		Fields: map[string]map[string]string{"Document.GrpHdr": {"Go": "GroupHeader"}},
+	}
+	strategy := NewOverrideNamingStrategy(GoNamingStrategy{}, "Go", overrides)
+
+	if got := strategy.TypeName("Document"); got != "CustomDocument" {
+		t.Errorf("TypeName() = %q, want CustomDocument", got)
+	}
+	if got := strategy.FieldName("GrpHdr", "Document"); got != "GroupHeader" {
+		t.Errorf("FieldName() = %q, want GroupHeader", got)
+	}
+	// Falls through to the wrapped strategy when there's no pinned name.
+	if got := strategy.TypeName("PmtInf"); got != "PmtInf" {
+		t.Errorf("TypeName() = %q, want PmtInf (GoNamingStrategy passthrough)", got)
+	}
+}
@@ -0,0 +1,302 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SchemaFetcher retrieves the raw bytes of a remote XSD document, resolving
+// relative xs:include/xs:import/schemaLocation references against parent.
+// Implementations are free to cache, authenticate, or refuse network access
+// entirely (e.g. in offline mode).
+type SchemaFetcher interface {
+	Fetch(location, parent string) ([]byte, error)
+}
+
+// HTTPFetcherOption configures an HTTPFetcher.
+type HTTPFetcherOption func(*HTTPFetcher)
+
+// WithTimeout sets the per-request timeout. Defaults to 30 seconds.
+func WithTimeout(d time.Duration) HTTPFetcherOption {
+	return func(f *HTTPFetcher) { f.Timeout = d }
+}
+
+// WithRetry sets the number of retries and the base backoff duration used
+// between attempts (doubled on each subsequent retry). Defaults to 3
+// retries with a 500ms base.
+func WithRetry(retries int, backoff time.Duration) HTTPFetcherOption {
+	return func(f *HTTPFetcher) {
+		f.Retries = retries
+		f.Backoff = backoff
+	}
+}
+
+// WithBasicAuth sets the username/password sent with every request.
+func WithBasicAuth(username, password string) HTTPFetcherOption {
+	return func(f *HTTPFetcher) { f.BasicAuth = &[2]string{username, password} }
+}
+
+// WithBearerToken sets the bearer token sent in the Authorization header.
+func WithBearerToken(token string) HTTPFetcherOption {
+	return func(f *HTTPFetcher) { f.BearerToken = token }
+}
+
+// WithHeader adds a custom header sent with every request, for private
+// schema registries that require e.g. an API key header.
+func WithHeader(key, value string) HTTPFetcherOption {
+	return func(f *HTTPFetcher) {
+		if f.Headers == nil {
+			f.Headers = make(map[string]string)
+		}
+		f.Headers[key] = value
+	}
+}
+
+// WithCacheDir enables on-disk caching of fetched documents, keyed by URL
+// and revalidated with ETag/Last-Modified, so an unchanged remote is not
+// downloaded again. Revalidation still sends a request; combined with
+// WithOffline, generation is served from the cache without network access.
+func WithCacheDir(dir string) HTTPFetcherOption {
+	return func(f *HTTPFetcher) { f.CacheDir = dir }
+}
+
+// WithOffline refuses any request that would require network access,
+// serving from cache only and returning an error on a cache miss.
+func WithOffline(offline bool) HTTPFetcherOption {
+	return func(f *HTTPFetcher) { f.Offline = offline }
+}
+
+// HTTPFetcher is the default SchemaFetcher. It fetches over HTTP(S) with a
+// configurable timeout and retry-with-backoff policy, and optionally caches
+// responses on disk so generation can be repeated offline.
+type HTTPFetcher struct {
+	Timeout     time.Duration
+	Retries     int
+	Backoff     time.Duration
+	BasicAuth   *[2]string
+	BearerToken string
+	Headers     map[string]string
+	CacheDir    string
+	Offline     bool
+
+	client http.Client
+}
+
+// NewHTTPFetcher returns an HTTPFetcher with the given options applied over
+// the package defaults.
+func NewHTTPFetcher(opts ...HTTPFetcherOption) *HTTPFetcher {
+	f := &HTTPFetcher{
+		Timeout: 30 * time.Second,
+		Retries: 3,
+		Backoff: 500 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	f.client = http.Client{Timeout: f.Timeout}
+	return f
+}
+
+// Fetch implements SchemaFetcher. location is resolved against parent when
+// it is not already an absolute URL.
+func (f *HTTPFetcher) Fetch(location, parent string) ([]byte, error) {
+	resolved, err := resolveSchemaLocation(location, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheKey := cacheKeyForURL(resolved)
+	cachedMeta, cached, cacheErr := f.readCache(cacheKey)
+
+	if f.Offline {
+		if cacheErr == nil {
+			return cached, nil
+		}
+		return nil, fmt.Errorf("offline mode: no cached copy of %s", resolved)
+	}
+
+	var body []byte
+	var meta cacheMeta
+	var lastErr error
+	for attempt := 0; attempt <= f.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(f.Backoff * time.Duration(1<<uint(attempt-1)))
+		}
+		body, meta, lastErr = f.doRequest(resolved, cachedMeta)
+		if lastErr == nil {
+			break
+		}
+	}
+	if lastErr != nil {
+		if cacheErr == nil {
+			return cached, nil
+		}
+		return nil, lastErr
+	}
+
+	if body == nil && cacheErr == nil {
+		// 304 Not Modified: the cached copy is still current.
+		return cached, nil
+	}
+
+	f.writeCache(cacheKey, body, meta)
+	return body, nil
+}
+
+// doRequest performs a single attempt and returns the body alongside the
+// cache validators from the response. It returns meta by value rather than
+// stashing it on f, so that two goroutines sharing an HTTPFetcher (e.g. the
+// package-level defaultFetcher, fetched concurrently by the worker pool)
+// never race over where the last response's validators were written.
+func (f *HTTPFetcher) doRequest(resolved string, meta cacheMeta) ([]byte, cacheMeta, error) {
+	req, err := http.NewRequest(http.MethodGet, resolved, nil)
+	if err != nil {
+		return nil, cacheMeta{}, err
+	}
+	if f.BasicAuth != nil {
+		req.SetBasicAuth(f.BasicAuth[0], f.BasicAuth[1])
+	}
+	if f.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+f.BearerToken)
+	}
+	for k, v := range f.Headers {
+		req.Header.Set(k, v)
+	}
+	if meta.ETag != "" {
+		req.Header.Set("If-None-Match", meta.ETag)
+	}
+	if meta.LastModified != "" {
+		req.Header.Set("If-Modified-Since", meta.LastModified)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, cacheMeta{}, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return nil, cacheMeta{}, nil
+	case http.StatusOK:
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, cacheMeta{}, err
+		}
+		return body, validatorsFromResponse(resp), nil
+	default:
+		return nil, cacheMeta{}, fmt.Errorf("fetchSchema: %s returned status %d", resolved, resp.StatusCode)
+	}
+}
+
+type cacheMeta struct {
+	ETag         string
+	LastModified string
+}
+
+// validatorsFromResponse extracts the cache validator headers from resp.
+func validatorsFromResponse(resp *http.Response) cacheMeta {
+	return cacheMeta{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+}
+
+func (f *HTTPFetcher) readCache(key string) (cacheMeta, []byte, error) {
+	if f.CacheDir == "" {
+		return cacheMeta{}, nil, fmt.Errorf("caching disabled")
+	}
+	body, err := ioutil.ReadFile(filepath.Join(f.CacheDir, key+".xsd"))
+	if err != nil {
+		return cacheMeta{}, nil, err
+	}
+	meta := readCacheMeta(filepath.Join(f.CacheDir, key+".meta"))
+	return meta, body, nil
+}
+
+func (f *HTTPFetcher) writeCache(key string, body []byte, meta cacheMeta) {
+	if f.CacheDir == "" || body == nil {
+		return
+	}
+	if err := PrepareOutputDir(f.CacheDir); err != nil {
+		return
+	}
+	_ = ioutil.WriteFile(filepath.Join(f.CacheDir, key+".xsd"), body, 0644)
+	writeCacheMeta(filepath.Join(f.CacheDir, key+".meta"), meta)
+}
+
+// readCacheMeta parses the ETag/Last-Modified pair written by
+// writeCacheMeta. It splits on the first newline rather than using
+// fmt.Sscanf("%s\n%s", ...), since "%s" stops at the first whitespace and
+// Last-Modified values always contain spaces (e.g. "Wed, 21 Oct 2015
+// 07:28:00 GMT"), which silently truncated every value to "Wed,".
+func readCacheMeta(path string) cacheMeta {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cacheMeta{}
+	}
+	parts := strings.SplitN(string(data), "\n", 2)
+	meta := cacheMeta{ETag: parts[0]}
+	if len(parts) == 2 {
+		meta.LastModified = parts[1]
+	}
+	return meta
+}
+
+func writeCacheMeta(path string, meta cacheMeta) {
+	_ = ioutil.WriteFile(path, []byte(meta.ETag+"\n"+meta.LastModified), 0644)
+}
+
+func cacheKeyForURL(resolved string) string {
+	sum := sha256.Sum256([]byte(resolved))
+	return hex.EncodeToString(sum[:])
+}
+
+// resolveSchemaLocation resolves an xs:include/xs:import schemaLocation
+// relative to the URL of the document that declared it.
+func resolveSchemaLocation(location, parent string) (string, error) {
+	if isValidURL(location) {
+		return location, nil
+	}
+	if parent == "" {
+		return location, nil
+	}
+	base, err := url.Parse(parent)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+var defaultFetcher SchemaFetcher = NewHTTPFetcher()
+
+// WithFetcher overrides the SchemaFetcher used to resolve remote
+// xs:include/xs:import/schemaLocation references during parsing. Pass a
+// HTTPFetcher configured with WithCacheDir and WithOffline to make
+// generation reproducible without network access.
+func WithFetcher(fetcher SchemaFetcher) ParserOption {
+	return func(p *Parser) { p.Fetcher = fetcher }
+}
+
+// ParserOption configures optional behaviour of Parser, following the
+// functional-options pattern.
+type ParserOption func(*Parser)
@@ -0,0 +1,70 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package xgen
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// TestReadCacheMetaPreservesSpaces guards against the fmt.Sscanf("%s\n%s")
+// regression, where "%s" stops at the first whitespace and truncated every
+// real Last-Modified header (which always contains spaces) to its first
+// word.
+func TestReadCacheMetaPreservesSpaces(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen-cachemeta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	want := cacheMeta{ETag: `"abc123"`, LastModified: "Wed, 21 Oct 2015 07:28:00 GMT"}
+	path := filepath.Join(dir, "entry.meta")
+	writeCacheMeta(path, want)
+
+	got := readCacheMeta(path)
+	if got != want {
+		t.Fatalf("readCacheMeta() = %+v, want %+v", got, want)
+	}
+}
+
+// TestHTTPFetcherConcurrentFetchNoRace exercises a single HTTPFetcher (as
+// the shared package-level defaultFetcher would be) from multiple
+// goroutines at once, each populating the cache. Run with `go test -race`
+// to confirm doRequest/writeCache no longer communicate through a shared
+// f.pendingMeta field.
+func TestHTTPFetcherConcurrentFetchNoRace(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+		_, _ = w.Write([]byte("<xs:schema/>"))
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "xgen-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fetcher := NewHTTPFetcher(WithCacheDir(dir))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := fetcher.Fetch(srv.URL, ""); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -10,8 +10,6 @@ package xgen
 
 import (
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -42,9 +40,8 @@ var (
 //
 // You may obtain a copy of this library at
 // https://github.com/GoPlasmatic/MXMessage`
-	matchFirstCap  = regexp.MustCompile("([A-Z])([A-Z][a-z])")
-	matchAllCap    = regexp.MustCompile("([a-z0-9])([A-Z])")
-	fieldNameCount map[string]int
+	matchFirstCap = regexp.MustCompile("([A-Z])([A-Z][a-z])")
+	matchAllCap   = regexp.MustCompile("([a-z0-9])([A-Z])")
 )
 
 // ToSnakeCase converts the provided string to snake_case.
@@ -55,23 +52,30 @@ func ToSnakeCase(input string) string {
 	return strings.ToLower(output)
 }
 
-// GetFileList get a list of file by given path.
+// GetFileList get a list of file by given path. When path is a directory,
+// only the regular files found while walking it are returned - the
+// directory itself (and any subdirectories) are never included, so callers
+// don't have to filter walk-reported directory entries back out.
 func GetFileList(path string) (files []string, err error) {
 	var fi os.FileInfo
 	fi, err = os.Stat(path)
 	if err != nil {
 		return
 	}
-	if fi.IsDir() {
-		err = filepath.Walk(path, func(fp string, info os.FileInfo, err error) error {
-			files = append(files, fp)
-			return nil
-		})
+	if !fi.IsDir() {
+		files = append(files, path)
+		return
+	}
+	err = filepath.Walk(path, func(fp string, info os.FileInfo, err error) error {
 		if err != nil {
-			return
+			return err
 		}
-	}
-	files = append(files, path)
+		if info.IsDir() {
+			return nil
+		}
+		files = append(files, fp)
+		return nil
+	})
 	return
 }
 
@@ -153,6 +157,10 @@ func getBuildInTypeByLang(value, lang string) (buildType string, ok bool) {
 		"Java":       3,
 		"Rust":       4,
 	}
+	if lang == "JSONSchema" {
+		kw, found := jsonSchemaBuildInTypes[value]
+		return kw["type"], found
+	}
 	var buildInTypes []string
 	if buildInTypes, ok = BuildInTypes[value]; !ok {
 		return
@@ -266,23 +274,12 @@ func isValidURL(toTest string) bool {
 	return true
 }
 
-func fetchSchema(URL string) ([]byte, error) {
-	var body []byte
-	var client http.Client
-	var err error
-	resp, err := client.Get(URL)
-	if err != nil {
-		return body, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusOK {
-		body, err = ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return body, err
-		}
-	}
-	return body, err
+// fetchSchema retrieves the raw bytes of a remote XSD document referenced by
+// an xs:include/xs:import/schemaLocation from parent, using the package
+// default SchemaFetcher. Call WithFetcher on the parser to customize
+// timeouts, retries, auth, caching, or offline behavior.
+func fetchSchema(URL, parent string) ([]byte, error) {
+	return defaultFetcher.Fetch(URL, parent)
 }
 
 func genFieldComment(name, doc, prefix string) string {
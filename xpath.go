@@ -0,0 +1,95 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// xpathExpr is a parsed "//tag[predicate]" selector. It supports exactly one
+// step ("//tag") and at most one predicate testing a single @attr with "="
+// or "starts-with" - enough to address schema declarations by kind and
+// name, which is all schemaNode exposes. It does not combine predicates
+// with "and"/"or", navigate multiple steps, or support any other XPath 1.0
+// axis or function, so selectors are documented as this specific subset
+// rather than as "XPath 1.0 expressions".
+type xpathExpr struct {
+	tag   string // "*", "simpleType", "complexType", "element", "attribute"
+	attr  string // attribute name tested by the predicate, usually "name"
+	op    string // "=", "starts-with"
+	value string
+}
+
+var xpathPattern = regexp.MustCompile(`^//(\*|\w+)(?:\[(.+)\])?$`)
+var xpathEqPattern = regexp.MustCompile(`^@(\w+)\s*=\s*'([^']*)'$`)
+var xpathStartsWithPattern = regexp.MustCompile(`^starts-with\(@(\w+),\s*'([^']*)'\)$`)
+
+// parseXPath parses a single-step selector such as
+// //complexType[@name='Document'] or //element[starts-with(@name,'pacs.008')].
+func parseXPath(expr string) (*xpathExpr, error) {
+	m := xpathPattern.FindStringSubmatch(strings.TrimSpace(expr))
+	if m == nil {
+		return nil, fmt.Errorf("xgen: unsupported XPath expression %q", expr)
+	}
+
+	x := &xpathExpr{tag: m[1]}
+	predicate := m[2]
+	if predicate == "" {
+		return x, nil
+	}
+
+	if pm := xpathEqPattern.FindStringSubmatch(predicate); pm != nil {
+		x.attr, x.op, x.value = pm[1], "=", pm[2]
+		return x, nil
+	}
+	if pm := xpathStartsWithPattern.FindStringSubmatch(predicate); pm != nil {
+		x.attr, x.op, x.value = pm[1], "starts-with", pm[2]
+		return x, nil
+	}
+	return nil, fmt.Errorf("xgen: unsupported XPath predicate %q", predicate)
+}
+
+// matches reports whether n satisfies x's tag and predicate.
+func (x *xpathExpr) matches(n schemaNode) bool {
+	if x.tag != "*" && x.tag != n.kind {
+		return false
+	}
+	if x.attr == "" {
+		return true
+	}
+	// schemaNode only ever exposes "name" as an attribute today.
+	if x.attr != "name" {
+		return false
+	}
+	switch x.op {
+	case "=":
+		return n.name == x.value
+	case "starts-with":
+		return strings.HasPrefix(n.name, x.value)
+	default:
+		return false
+	}
+}
+
+// matchXPath evaluates expr against nodes and records every matching
+// declaration's (kind, name) into matched.
+func matchXPath(nodes []schemaNode, expr string, matched map[nodeKey]bool) error {
+	x, err := parseXPath(expr)
+	if err != nil {
+		return err
+	}
+	for _, n := range nodes {
+		if x.matches(n) {
+			matched[nodeKey{kind: n.kind, name: n.name}] = true
+		}
+	}
+	return nil
+}
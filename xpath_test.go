@@ -0,0 +1,64 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package xgen
+
+import "testing"
+
+func TestParseXPath(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{expr: "//complexType[@name='Document']"},
+		{expr: "//element[starts-with(@name,'pacs.008')]"},
+		{expr: "//*"},
+		{expr: "//complexType"},
+		{expr: "not-an-xpath-expression", wantErr: true},
+		{expr: "//complexType[@name='Document' and @foo='bar']", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		_, err := parseXPath(tt.expr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseXPath(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+		}
+	}
+}
+
+func TestXpathExprMatches(t *testing.T) {
+	nodes := []schemaNode{
+		{kind: "complexType", name: "Document"},
+		{kind: "element", name: "Document"},
+		{kind: "element", name: "pacs.008.001.08"},
+	}
+
+	x, err := parseXPath("//complexType[@name='Document']")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, n := range nodes {
+		if x.matches(n) {
+			got = append(got, n.kind+":"+n.name)
+		}
+	}
+	if len(got) != 1 || got[0] != "complexType:Document" {
+		t.Fatalf("matches() = %v, want only complexType:Document", got)
+	}
+
+	x, err = parseXPath("//element[starts-with(@name,'pacs.008')]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	for _, n := range nodes {
+		if x.matches(n) {
+			got = append(got, n.kind+":"+n.name)
+		}
+	}
+	if len(got) != 1 || got[0] != "element:pacs.008.001.08" {
+		t.Fatalf("matches() = %v, want only element:pacs.008.001.08", got)
+	}
+}